@@ -0,0 +1,43 @@
+package backoff
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// BackOffContext is a BackOff that is bound to a context.Context. It lets
+// callers build a context-bound policy once and reuse it across many Retry
+// calls, without having to thread the context through RetryNotifyWithContext
+// each time.
+type BackOffContext interface {
+	BackOff
+	Context() context.Context
+}
+
+type backOffContext struct {
+	BackOff
+	ctx context.Context
+}
+
+// WithContext returns a BackOffContext wrapping b, whose NextBackOff()
+// returns Stop once ctx is done.
+func WithContext(b BackOff, ctx context.Context) BackOffContext {
+	if cb, ok := b.(*backOffContext); ok {
+		return &backOffContext{cb.BackOff, ctx}
+	}
+	return &backOffContext{b, ctx}
+}
+
+func (b *backOffContext) Context() context.Context {
+	return b.ctx
+}
+
+func (b *backOffContext) NextBackOff() time.Duration {
+	select {
+	case <-b.ctx.Done():
+		return Stop
+	default:
+		return b.BackOff.NextBackOff()
+	}
+}
@@ -0,0 +1,24 @@
+package backoff
+
+// PermanentError signals that the wrapped error should not be retried, no
+// matter what the BackOff policy or remaining attempts say.
+type PermanentError struct {
+	Err error
+}
+
+// Permanent wraps the given err in a *PermanentError, telling Retry and
+// RetryNotify to stop retrying immediately and return err.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
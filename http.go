@@ -0,0 +1,157 @@
+package backoff
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// retryableStatusCodes are HTTP response status codes that indicate the
+// request can be retried as-is.
+var retryableStatusCodes = map[int]bool{
+	http.StatusTooManyRequests:     true,
+	http.StatusInternalServerError: true,
+	http.StatusBadGateway:          true,
+	http.StatusServiceUnavailable:  true,
+	http.StatusGatewayTimeout:      true,
+}
+
+// RetryHTTP retries do using b as the backoff policy between attempts. A
+// transport error is retried when it is a net.Error with Temporary() or
+// Timeout() true, or io.ErrUnexpectedEOF; any other transport error is
+// terminal. A response with status 429, 500, 502, 503 or 504 is retried; any
+// other response is returned to the caller as-is, without further retries.
+// If retries are exhausted while the last response still had a retryable
+// status, that last response is returned instead of an error, exactly as if
+// it had been non-retryable.
+//
+// Every response but the one finally returned to the caller has its body
+// drained and closed.
+//
+// If a retryable response carries a Retry-After header (delta-seconds or
+// HTTP-date), it overrides the next sleep whenever it is larger than what b
+// would otherwise produce.
+func RetryHTTP(ctx context.Context, b BackOff, do func() (*http.Response, error)) (*http.Response, error) {
+	ob := &overridingBackOff{BackOff: b}
+
+	var resp *http.Response
+	operation := func() error {
+		r, err := do()
+
+		// Whatever this attempt produced, the response stored from the
+		// previous attempt (necessarily retryable, since a terminal one
+		// would have already ended the loop) is superseded and must be
+		// drained before it's forgotten or replaced.
+		if resp != nil {
+			drainAndClose(resp)
+			resp = nil
+		}
+
+		if err != nil {
+			if isTemporaryNetErr(err) {
+				return err
+			}
+			return Permanent(err)
+		}
+
+		if !retryableStatusCodes[r.StatusCode] {
+			resp = r
+			return nil
+		}
+
+		resp = r
+
+		if d, ok := parseRetryAfter(r.Header.Get("Retry-After")); ok {
+			ob.override = d
+		}
+		return &httpStatusError{StatusCode: r.StatusCode}
+	}
+
+	err := RetryNotifyWithContext(ctx, operation, ob, nil)
+	if err == nil {
+		return resp, nil
+	}
+
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return resp, nil
+	}
+	if resp != nil {
+		drainAndClose(resp)
+	}
+	return nil, err
+}
+
+// httpStatusError records a retryable HTTP status code so that the retry
+// loop has a non-nil error to act on. RetryHTTP never returns it to its
+// caller: once retries are exhausted while it is the current error,
+// RetryHTTP returns the last (undrained) response instead.
+type httpStatusError struct {
+	StatusCode int
+}
+
+func (e *httpStatusError) Error() string {
+	return "backoff: retryable HTTP status " + strconv.Itoa(e.StatusCode)
+}
+
+// overridingBackOff wraps a BackOff, letting a single upcoming NextBackOff()
+// call be overridden (e.g. by a Retry-After header) whenever the override is
+// larger than what the wrapped BackOff would produce on its own.
+type overridingBackOff struct {
+	BackOff
+	override time.Duration
+}
+
+func (o *overridingBackOff) NextBackOff() time.Duration {
+	next := o.BackOff.NextBackOff()
+	if next == Stop {
+		return Stop
+	}
+	if o.override > next {
+		next = o.override
+	}
+	o.override = 0
+	return next
+}
+
+func isTemporaryNetErr(err error) bool {
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return netErr.Temporary() || netErr.Timeout()
+	}
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header value in either its
+// delta-seconds or HTTP-date form.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+func drainAndClose(r *http.Response) {
+	io.Copy(ioutil.Discard, r.Body)
+	r.Body.Close()
+}
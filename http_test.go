@@ -0,0 +1,219 @@
+package backoff
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// countingBackOff is a minimal BackOff test double that allows a fixed
+// number of retries before returning Stop.
+type countingBackOff struct {
+	max   int
+	calls int
+}
+
+func (b *countingBackOff) NextBackOff() time.Duration {
+	b.calls++
+	if b.calls > b.max {
+		return Stop
+	}
+	return time.Millisecond
+}
+
+func (b *countingBackOff) Reset() { b.calls = 0 }
+
+type closeTrackingBody struct {
+	*bytes.Reader
+	closed bool
+}
+
+func (c *closeTrackingBody) Close() error {
+	c.closed = true
+	return nil
+}
+
+func newResponse(status int) (*http.Response, *closeTrackingBody) {
+	body := &closeTrackingBody{Reader: bytes.NewReader([]byte("body"))}
+	return &http.Response{StatusCode: status, Header: http.Header{}, Body: body}, body
+}
+
+type temporaryErr struct{}
+
+func (temporaryErr) Error() string   { return "temporary" }
+func (temporaryErr) Timeout() bool   { return false }
+func (temporaryErr) Temporary() bool { return true }
+
+func TestRetryHTTP_NonRetryableStatusReturnsImmediately(t *testing.T) {
+	calls := 0
+	resp, body := newResponse(http.StatusNotFound)
+	got, err := RetryHTTP(nil, &countingBackOff{max: 5}, func() (*http.Response, error) {
+		calls++
+		return resp, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != resp {
+		t.Fatalf("expected the 404 response to be returned as-is")
+	}
+	if calls != 1 {
+		t.Fatalf("expected exactly 1 call, got %d", calls)
+	}
+	if body.closed {
+		t.Fatalf("returned response body should not be closed")
+	}
+}
+
+func TestRetryHTTP_RetryableStatusEventuallySucceeds(t *testing.T) {
+	var retried []*closeTrackingBody
+	calls := 0
+	ok, okBody := newResponse(http.StatusOK)
+	got, err := RetryHTTP(nil, &countingBackOff{max: 5}, func() (*http.Response, error) {
+		calls++
+		if calls < 3 {
+			r, b := newResponse(http.StatusServiceUnavailable)
+			retried = append(retried, b)
+			return r, nil
+		}
+		return ok, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != ok {
+		t.Fatalf("expected the eventual 200 response to be returned")
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+	for i, b := range retried {
+		if !b.closed {
+			t.Fatalf("retried response %d should have had its body drained and closed", i)
+		}
+	}
+	if okBody.closed {
+		t.Fatalf("final response body should not be closed")
+	}
+}
+
+func TestRetryHTTP_RetryableStatusExhaustionReturnsLastResponse(t *testing.T) {
+	var last *http.Response
+	var lastBody *closeTrackingBody
+	got, err := RetryHTTP(nil, &countingBackOff{max: 2}, func() (*http.Response, error) {
+		last, lastBody = newResponse(http.StatusServiceUnavailable)
+		return last, nil
+	})
+	if err != nil {
+		t.Fatalf("expected exhaustion to return the last response, not an error: %v", err)
+	}
+	if got != last {
+		t.Fatalf("expected the last 503 response to be returned")
+	}
+	if lastBody.closed {
+		t.Fatalf("the last response's body must not be drained/closed since it is handed to the caller")
+	}
+}
+
+func TestRetryHTTP_WrappedTemporaryTransportErrorIsRetried(t *testing.T) {
+	calls := 0
+	ok, _ := newResponse(http.StatusOK)
+	got, err := RetryHTTP(nil, &countingBackOff{max: 5}, func() (*http.Response, error) {
+		calls++
+		if calls < 2 {
+			return nil, fmt.Errorf("dial tcp: %w", temporaryErr{})
+		}
+		return ok, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != ok || calls != 2 {
+		t.Fatalf("expected the wrapped transient error to be retried once, got calls=%d got=%v", calls, got)
+	}
+}
+
+func TestRetryHTTP_WrappedUnexpectedEOFIsRetried(t *testing.T) {
+	calls := 0
+	ok, _ := newResponse(http.StatusOK)
+	got, err := RetryHTTP(nil, &countingBackOff{max: 5}, func() (*http.Response, error) {
+		calls++
+		if calls < 2 {
+			return nil, fmt.Errorf("read body: %w", io.ErrUnexpectedEOF)
+		}
+		return ok, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != ok || calls != 2 {
+		t.Fatalf("expected the wrapped io.ErrUnexpectedEOF to be retried once, got calls=%d got=%v", calls, got)
+	}
+}
+
+func TestRetryHTTP_PermanentTransportErrorStopsImmediately(t *testing.T) {
+	calls := 0
+	wantErr := errors.New("boom")
+	_, err := RetryHTTP(nil, &countingBackOff{max: 5}, func() (*http.Response, error) {
+		calls++
+		return nil, wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("expected the permanent error to be returned, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected exactly 1 call, got %d", calls)
+	}
+}
+
+func TestRetryHTTP_RetryAfterOverridesBackOff(t *testing.T) {
+	calls := 0
+	ok, _ := newResponse(http.StatusOK)
+	start := time.Now()
+	got, err := RetryHTTP(nil, &countingBackOff{max: 5}, func() (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			r, _ := newResponse(http.StatusServiceUnavailable)
+			r.Header.Set("Retry-After", "1")
+			return r, nil
+		}
+		return ok, nil
+	})
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != ok || calls != 2 {
+		t.Fatalf("expected one retry honoring Retry-After, got calls=%d got=%v", calls, got)
+	}
+	if elapsed < time.Second {
+		t.Fatalf("expected the 1s Retry-After to override the 1ms backoff, slept only %v", elapsed)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	cases := []struct {
+		value   string
+		wantOK  bool
+		wantDur time.Duration
+	}{
+		{"", false, 0},
+		{"120", true, 120 * time.Second},
+		{"-5", false, 0},
+		{"not-a-date", false, 0},
+	}
+	for _, c := range cases {
+		d, ok := parseRetryAfter(c.value)
+		if ok != c.wantOK {
+			t.Errorf("parseRetryAfter(%q) ok = %v, want %v", c.value, ok, c.wantOK)
+			continue
+		}
+		if ok && d != c.wantDur {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", c.value, d, c.wantDur)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package backoff
 
 import (
+	"errors"
 	"time"
 
 	"golang.org/x/net/context"
@@ -8,21 +9,34 @@ import (
 
 // An Operation is executing by Retry() or RetryNotify().
 // The operation will be retried using a backoff policy if it returns an error.
+//
+// If the operation returns an error wrapped with Permanent, the retry loop
+// stops immediately and returns the wrapped error, without consulting the
+// BackOff or calling Notify.
 type Operation func() error
 
 // Notify is a notify-on-error function. It receives an operation error and
 // backoff delay if the operation failed (with an error).
 //
-// NOTE that if the backoff policy stated to stop retrying,
-// the notify function isn't called.
+// NOTE that if the backoff policy stated to stop retrying, or the context
+// passed to RetryNotifyWithContext is done, the notify function isn't
+// called.
 type Notify func(error, time.Duration)
 
+// SuccessNotify is a notify-on-success function. It is called exactly once,
+// when the operation finally succeeds after one or more failed attempts,
+// and receives the number of failed attempts that preceded the success.
+type SuccessNotify func(retries int)
+
 // Retry the function f until it does not return error or BackOff stops.
 // f is guaranteed to be run at least once.
 // It is the caller's responsibility to reset b after Retry returns.
 //
 // Retry sleeps the goroutine for the duration returned by BackOff after a
 // failed operation returns.
+//
+// If b implements BackOffContext, its Context() is used automatically, as
+// if RetryNotifyWithContext had been called with it directly.
 func Retry(o Operation, b BackOff) error { return RetryNotify(o, b, nil) }
 
 // RetryNotify calls notify function with the error and wait duration
@@ -31,12 +45,39 @@ func RetryNotify(operation Operation, b BackOff, notify Notify) error {
 	return RetryNotifyWithContext(nil, operation, b, notify)
 }
 
+// RetryNotifyWithSuccess calls notify function with the error and wait
+// duration for each failed attempt before sleep, and calls success exactly
+// once with the number of failed attempts when operation finally succeeds,
+// but only if there was at least one prior failure.
+func RetryNotifyWithSuccess(operation Operation, b BackOff, notify Notify, success SuccessNotify) error {
+	return RetryNotifyWithContextAndSuccess(nil, operation, b, notify, success)
+}
+
 // RetryNotifyWithContext calls notify function with the error and
 // wait duration for each failed attempt before sleep. If ctx is
 // non-nil, it will return early from a sleep when it's Done channel
-// is closed.
+// is closed, and if ctx is already done by the time the loop would
+// otherwise give up or sleep, ctx.Err() is returned instead of the last
+// operation error.
 func RetryNotifyWithContext(ctx context.Context, operation Operation,
 	b BackOff, notify Notify) error {
+	return RetryNotifyWithContextAndSuccess(ctx, operation, b, notify, nil)
+}
+
+// RetryNotifyWithContextAndSuccess is like RetryNotifyWithContext, but also
+// calls success exactly once with the number of failed attempts when
+// operation finally succeeds, but only if there was at least one prior
+// failure.
+func RetryNotifyWithContextAndSuccess(ctx context.Context, operation Operation,
+	b BackOff, notify Notify, success SuccessNotify) error {
+	// If the BackOff is already bound to a context, use it unless the
+	// caller explicitly supplied one of its own.
+	if ctx == nil {
+		if cb, ok := b.(BackOffContext); ok {
+			ctx = cb.Context()
+		}
+	}
+
 	// If context is already canceled, return immediately.
 	if ctx != nil {
 		select {
@@ -48,13 +89,34 @@ func RetryNotifyWithContext(ctx context.Context, operation Operation,
 
 	var err error
 	var next time.Duration
+	var retries int
 
 	b.Reset()
 	for {
 		if err = operation(); err == nil {
+			if retries > 0 && success != nil {
+				success(retries)
+			}
 			return nil
 		}
 
+		var permanent *PermanentError
+		if errors.As(err, &permanent) {
+			return permanent.Err
+		}
+
+		// A caller who canceled ctx gets ctx.Err() rather than this
+		// attempt's error, whether or not the BackOff has more retries
+		// left: the cancellation, not the last failure, is what ended
+		// the loop.
+		if ctx != nil {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+		}
+
 		if next = b.NextBackOff(); next == Stop {
 			return err
 		}
@@ -63,6 +125,8 @@ func RetryNotifyWithContext(ctx context.Context, operation Operation,
 			notify(err, next)
 		}
 
+		retries++
+
 		if ctx != nil {
 			select {
 			case <-time.After(next):
@@ -0,0 +1,108 @@
+package backoff
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// Ticker holds a channel that delivers `ticks' of a clock at times reported
+// by a BackOff.
+//
+// Ticks will continue to arrive when the previous operation is still
+// running, so operations that take a while to run may need to check that
+// the ticker hasn't been stopped before acting on a tick that arrived while
+// the operation was in flight.
+//
+// Ticker is guaranteed to tick at least once. The channel is closed when
+// Stop is called, or when the underlying BackOff's NextBackOff returns
+// Stop.
+type Ticker struct {
+	C        <-chan time.Time
+	c        chan time.Time
+	b        BackOff
+	ctx      context.Context
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewTicker returns a new Ticker containing a channel that will send the
+// time at times specified by the BackOff argument. NewTicker calls
+// b.Reset() before returning, and stops once b.NextBackOff() returns Stop.
+//
+// Stop must be called when the ticker is no longer needed, to release
+// associated resources.
+func NewTicker(b BackOff) *Ticker {
+	return NewTickerWithContext(nil, b)
+}
+
+// NewTickerWithContext is like NewTicker, but also stops the ticker once
+// ctx is done, mirroring the semantics of RetryNotifyWithContext.
+func NewTickerWithContext(ctx context.Context, b BackOff) *Ticker {
+	c := make(chan time.Time)
+	t := &Ticker{
+		C:    c,
+		c:    c,
+		b:    b,
+		ctx:  ctx,
+		stop: make(chan struct{}),
+	}
+	t.b.Reset()
+	go t.run()
+	return t
+}
+
+// Stop turns off the ticker. It is safe to call Stop more than once, and
+// safe to call it from a goroutine other than the one running the ticker.
+func (t *Ticker) Stop() {
+	t.stopOnce.Do(func() { close(t.stop) })
+}
+
+func (t *Ticker) run() {
+	c := t.c
+	defer close(c)
+
+	// Ticker is guaranteed to tick at least once.
+	afterC := t.send(time.Now())
+
+	for {
+		if afterC == nil {
+			return
+		}
+
+		if t.ctx != nil {
+			select {
+			case tick := <-afterC:
+				afterC = t.send(tick)
+			case <-t.stop:
+				return
+			case <-t.ctx.Done():
+				return
+			}
+		} else {
+			select {
+			case tick := <-afterC:
+				afterC = t.send(tick)
+			case <-t.stop:
+				return
+			}
+		}
+	}
+}
+
+func (t *Ticker) send(tick time.Time) <-chan time.Time {
+	select {
+	case t.c <- tick:
+	case <-t.stop:
+		return nil
+	}
+
+	next := t.b.NextBackOff()
+	if next == Stop {
+		t.Stop()
+		return nil
+	}
+
+	return time.After(next)
+}